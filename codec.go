@@ -0,0 +1,84 @@
+package peer
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// SignalingCodecJSON and SignalingCodecMsgpack are the Options.SignalingCodec
+// values recognised by Socket. JSON is the default and keeps wire
+// compatibility with the JS peerjs-server; msgpack trims bandwidth on
+// chatty Go-to-Go renegotiations with large SDPs.
+const (
+	SignalingCodecJSON    = "json"
+	SignalingCodecMsgpack = "msgpack"
+)
+
+// textFrame and binaryFrame mirror websocket.TextMessage/BinaryMessage so
+// this file doesn't need to import gorilla/websocket just for two ints.
+const (
+	textFrame   = 1
+	binaryFrame = 2
+)
+
+// codec encodes/decodes a Message for the signaling wire.
+type codec interface {
+	// Encode returns the wire bytes for msg and the websocket frame type
+	// (websocket.TextMessage or websocket.BinaryMessage) they should be
+	// sent as.
+	Encode(msg *Message) (frameType int, data []byte, err error)
+	// Decode parses wire bytes received on the given frame type into msg.
+	Decode(frameType int, data []byte, msg *Message) error
+}
+
+// jsonCodec is the default, wire-compatible with the reference JS server.
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(msg *Message) (int, []byte, error) {
+	data, err := json.Marshal(msg)
+	return textFrame, data, err
+}
+
+func (jsonCodec) Decode(frameType int, data []byte, msg *Message) error {
+	return json.Unmarshal(data, msg)
+}
+
+// msgpackCodec trades JSON compatibility for a more compact binary framing.
+// It round-trips through Message's JSON (un)marshalling rather than packing
+// the struct directly, so Payload's Extra-preserving UnmarshalJSON/MarshalJSON
+// still run instead of being bypassed by a plain msgpack struct (de)code.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Encode(msg *Message) (int, []byte, error) {
+	asJSON, err := json.Marshal(msg)
+	if err != nil {
+		return binaryFrame, nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(asJSON, &generic); err != nil {
+		return binaryFrame, nil, err
+	}
+	data, err := msgpack.Marshal(generic)
+	return binaryFrame, data, err
+}
+
+func (msgpackCodec) Decode(frameType int, data []byte, msg *Message) error {
+	var generic interface{}
+	if err := msgpack.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	asJSON, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(asJSON, msg)
+}
+
+// codecFor resolves Options.SignalingCodec to a codec, defaulting to JSON.
+func codecFor(name string) codec {
+	if name == SignalingCodecMsgpack {
+		return msgpackCodec{}
+	}
+	return jsonCodec{}
+}
@@ -0,0 +1,41 @@
+package peer
+
+import "sync"
+
+// EventHandler receives the data passed to Emit for an event it's
+// subscribed to.
+type EventHandler func(data interface{})
+
+// Emitter is a minimal event-emitter primitive embedded by Socket and other
+// transport/Peer types, so they can expose an On/Emit style API without
+// each one reimplementing subscriber bookkeeping.
+type Emitter struct {
+	mutex    sync.Mutex
+	handlers map[string][]EventHandler
+}
+
+// NewEmitter creates a ready-to-use Emitter.
+func NewEmitter() Emitter {
+	return Emitter{handlers: map[string][]EventHandler{}}
+}
+
+// On registers handler to be called every time event is emitted.
+func (e *Emitter) On(event string, handler EventHandler) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.handlers == nil {
+		e.handlers = map[string][]EventHandler{}
+	}
+	e.handlers[event] = append(e.handlers[event], handler)
+}
+
+// Emit calls every handler registered for event with data.
+func (e *Emitter) Emit(event string, data interface{}) {
+	e.mutex.Lock()
+	handlers := append([]EventHandler{}, e.handlers[event]...)
+	e.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(data)
+	}
+}
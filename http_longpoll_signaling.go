@@ -0,0 +1,143 @@
+package peer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// httpLongPollTimeout is roughly how long the reference PeerServer holds a
+// long-poll request open before returning an empty batch so the client can
+// re-poll. The HTTP client's timeout, used for polls and posts alike, is this
+// plus a 5 second margin.
+const httpLongPollTimeout = 30 * time.Second
+
+// HTTPLongPollSignaling is a Signaling implementation for networks or
+// platforms (serverless, restrictive proxies) where a persistent websocket
+// is awkward. It POSTs outgoing Messages to .../post and long-polls
+// .../poll for incoming ones, matching the reference PeerServer's HTTP API.
+type HTTPLongPollSignaling struct {
+	Emitter
+	id      string
+	token   string
+	opts    Options
+	baseURL string
+	client  *http.Client
+	log     *logrus.Entry
+
+	mutex        sync.Mutex
+	disconnected bool
+}
+
+// NewHTTPLongPollSignaling creates an HTTP long-poll backed Signaling.
+func NewHTTPLongPollSignaling(opts Options) *HTTPLongPollSignaling {
+	return &HTTPLongPollSignaling{
+		Emitter:      NewEmitter(),
+		opts:         opts,
+		client:       &http.Client{Timeout: httpLongPollTimeout + (5 * time.Second)},
+		disconnected: true,
+		log:          createLogger("http-signaling", opts.Debug),
+	}
+}
+
+func (h *HTTPLongPollSignaling) buildBaseURL() string {
+	proto := "http"
+	if h.opts.Secure {
+		proto = "https"
+	}
+	port := strconv.Itoa(h.opts.Port)
+	return fmt.Sprintf(
+		"%s://%s:%s%s/peerjs",
+		proto,
+		h.opts.Host,
+		port,
+		h.opts.Path,
+	)
+}
+
+// isDisconnected reports whether the signaling is currently stopped.
+func (h *HTTPLongPollSignaling) isDisconnected() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.disconnected
+}
+
+func (h *HTTPLongPollSignaling) setDisconnected(v bool) {
+	h.mutex.Lock()
+	h.disconnected = v
+	h.mutex.Unlock()
+}
+
+// Start begins long-polling the server for the given id/token.
+func (h *HTTPLongPollSignaling) Start(id string, token string) error {
+	if !h.isDisconnected() {
+		return nil
+	}
+
+	h.id = id
+	h.token = token
+	if h.baseURL == "" {
+		h.baseURL = h.buildBaseURL()
+	}
+	h.setDisconnected(false)
+
+	go h.pollLoop()
+
+	return nil
+}
+
+func (h *HTTPLongPollSignaling) pollURL() string {
+	return fmt.Sprintf("%s/%s/%s/poll", h.baseURL, h.id, h.token)
+}
+
+func (h *HTTPLongPollSignaling) postURL() string {
+	return fmt.Sprintf("%s/%s/%s/post", h.baseURL, h.id, h.token)
+}
+
+func (h *HTTPLongPollSignaling) pollLoop() {
+	for !h.isDisconnected() {
+		resp, err := h.client.Get(h.pollURL())
+		if err != nil {
+			h.log.Warnf("Long-poll request failed: %s", err)
+			h.setDisconnected(true)
+			h.Emit(SocketEventTypeDisconnected, SocketEvent{SocketEventTypeDisconnected, nil, err})
+			return
+		}
+
+		var msgs []Message
+		err = json.NewDecoder(resp.Body).Decode(&msgs)
+		resp.Body.Close()
+		if err != nil {
+			h.log.Errorf("Failed to decode long-poll batch: %s", err)
+			continue
+		}
+
+		for i := range msgs {
+			h.Emit(SocketEventTypeMessage, SocketEvent{SocketEventTypeMessage, &msgs[i], nil})
+		}
+	}
+}
+
+// Send POSTs a single Message to the server's /post endpoint.
+func (h *HTTPLongPollSignaling) Send(msg []byte) error {
+	if h.isDisconnected() {
+		return nil
+	}
+	resp, err := h.client.Post(h.postURL(), "application/json", bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Close stops the long-poll loop.
+func (h *HTTPLongPollSignaling) Close() error {
+	h.setDisconnected(true)
+	return nil
+}
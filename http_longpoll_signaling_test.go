@@ -0,0 +1,96 @@
+package peer
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPLongPollSignaling_PollAndSend(t *testing.T) {
+	var polls int32
+	var posted atomic.Value
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/peerjs/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/poll"):
+			if atomic.AddInt32(&polls, 1) == 1 {
+				json.NewEncoder(w).Encode([]Message{{Type: "OFFER", Src: "remote"}})
+				return
+			}
+			// Slow down later polls instead of busy-looping, mirroring the
+			// reference server holding the request open.
+			time.Sleep(20 * time.Millisecond)
+			json.NewEncoder(w).Encode([]Message{})
+		case strings.HasSuffix(r.URL.Path, "/post"):
+			var msg Message
+			if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			posted.Store(msg)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	addr := server.Listener.Addr().(*net.TCPAddr)
+
+	h := NewHTTPLongPollSignaling(Options{Host: "127.0.0.1", Port: addr.Port})
+	messages := make(chan *Message, 1)
+	h.On(SocketEventTypeMessage, func(data interface{}) {
+		if event, ok := data.(SocketEvent); ok {
+			messages <- event.Message
+		}
+	})
+
+	if err := h.Start("peer1", "token1"); err != nil {
+		t.Fatalf("Start failed: %s", err)
+	}
+	defer h.Close()
+
+	select {
+	case msg := <-messages:
+		if msg.Type != "OFFER" {
+			t.Fatalf("expected polled OFFER message, got %q", msg.Type)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for polled message")
+	}
+
+	data, err := json.Marshal(&Message{Type: "ANSWER", Src: "peer1"})
+	if err != nil {
+		t.Fatalf("marshal failed: %s", err)
+	}
+	if err := h.Send(data); err != nil {
+		t.Fatalf("Send failed: %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if msg, ok := posted.Load().(Message); ok {
+			if msg.Type != "ANSWER" {
+				t.Fatalf("expected posted ANSWER message, got %q", msg.Type)
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := posted.Load().(Message); !ok {
+		t.Fatal("server never received the posted message")
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+	if !h.isDisconnected() {
+		t.Fatal("expected isDisconnected() to be true after Close")
+	}
+}
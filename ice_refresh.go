@@ -0,0 +1,42 @@
+package peer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// RefreshICEMessageType is a non-standard signaling message a custom
+// PeerServer plugin can send to push rotated TURN credentials to a
+// long-lived peer without it having to reconnect.
+const RefreshICEMessageType = "REFRESH_ICE"
+
+// refreshICEPayload is the shape of Payload.Extra for a RefreshICEMessageType
+// message: a replacement ICE server list.
+type refreshICEPayload struct {
+	ICEServers []webrtc.ICEServer `json:"iceServers"`
+}
+
+// ParseRefreshICE extracts the new ICE server list from a REFRESH_ICE
+// message's Payload.Extra.
+func ParseRefreshICE(p Payload) ([]webrtc.ICEServer, error) {
+	raw, ok := p.Extra["iceServers"]
+	if !ok {
+		return nil, fmt.Errorf("peer: REFRESH_ICE message missing iceServers")
+	}
+	var servers []webrtc.ICEServer
+	if err := json.Unmarshal(raw, &servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// ApplyRefreshICE pushes a new ICE server list into a live PeerConnection's
+// configuration via SetConfiguration, so in-flight connections pick up
+// rotated TURN credentials without being torn down.
+func ApplyRefreshICE(pc *webrtc.PeerConnection, servers []webrtc.ICEServer) error {
+	cfg := pc.GetConfiguration()
+	cfg.ICEServers = servers
+	return pc.SetConfiguration(cfg)
+}
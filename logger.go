@@ -0,0 +1,14 @@
+package peer
+
+import "github.com/sirupsen/logrus"
+
+// createLogger returns a logger scoped to component, at debug level when
+// debug is true and info level otherwise.
+func createLogger(component string, debug bool) *logrus.Entry {
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	if debug {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+	return logger.WithField("component", component)
+}
@@ -1,6 +1,10 @@
 package peer
 
-import "github.com/pion/webrtc/v3"
+import (
+	"encoding/json"
+
+	"github.com/pion/webrtc/v3"
+)
 
 // Payload wraps a message payload
 type Payload struct {
@@ -13,6 +17,67 @@ type Payload struct {
 	Candidate     string                     `json:"candidate,omitempty"`
 	SDP           *webrtc.SessionDescription `json:"sdp,omitempty"`
 	Browser       string                     `json:"browser,omitempty"`
+
+	// Extra holds fields sent by custom PeerServer plugins (rotated TURN
+	// credentials, presence, app-specific events, ...) that aren't part of
+	// the reference server's payload shape, so they survive unmarshalling
+	// instead of being silently dropped.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// payloadKnownFields lists the JSON keys already bound to named fields on
+// Payload, so UnmarshalJSON knows what to exclude from Extra.
+var payloadKnownFields = map[string]bool{
+	"type": true, "connectionId": true, "metadata": true, "label": true,
+	"serialization": true, "reliable": true, "candidate": true, "sdp": true,
+	"browser": true,
+}
+
+// UnmarshalJSON decodes the known Payload fields as usual and preserves any
+// unrecognized keys in Extra instead of dropping them.
+func (p *Payload) UnmarshalJSON(data []byte) error {
+	type payloadAlias Payload
+	var alias payloadAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*p = Payload(alias)
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key := range raw {
+		if payloadKnownFields[key] {
+			delete(raw, key)
+		}
+	}
+	if len(raw) > 0 {
+		p.Extra = raw
+	}
+	return nil
+}
+
+// MarshalJSON serializes the known Payload fields and merges Extra back in,
+// so fields round-tripped through UnmarshalJSON aren't lost on re-encode.
+func (p Payload) MarshalJSON() ([]byte, error) {
+	type payloadAlias Payload
+	known, err := json.Marshal(payloadAlias(p))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Extra) == 0 {
+		return known, nil
+	}
+
+	merged := map[string]json.RawMessage{}
+	if err := json.Unmarshal(known, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range p.Extra {
+		merged[key] = value
+	}
+	return json.Marshal(merged)
 }
 
 // Message the IMessage implementation
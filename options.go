@@ -0,0 +1,62 @@
+package peer
+
+import (
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// Options configures a Peer and the Signaling transport it drives.
+type Options struct {
+	// Debug enables debug-level logging on the socket/peer loggers.
+	Debug bool
+	// Secure selects wss/https when true, ws/http otherwise.
+	Secure bool
+	// Host is the PeerServer host to connect to.
+	Host string
+	// Port is the PeerServer port to connect to.
+	Port int
+	// Path is the PeerServer base path (e.g. "/myapp").
+	Path string
+	// Key is the PeerServer API key.
+	Key string
+	// PingInterval is the protocol-level ping/heartbeat interval, in
+	// milliseconds.
+	PingInterval int
+	// PingTimeoutFactor is multiplied by PingInterval to get how long to
+	// wait without receiving anything (pong, heartbeat ack or any other
+	// message) before the link is considered dead;
+	// DefaultPingTimeoutFactor is used when unset.
+	PingTimeoutFactor float64
+
+	// ReconnectEnabled turns on the supervised reconnect loop when the
+	// signaling transport drops.
+	ReconnectEnabled bool
+	// ReconnectInitialDelay is the first backoff delay before redialing;
+	// DefaultReconnectInitialDelay is used when unset.
+	ReconnectInitialDelay time.Duration
+	// ReconnectMaxDelay caps the exponential backoff between attempts,
+	// jitter included; DefaultReconnectMaxDelay is used when unset.
+	ReconnectMaxDelay time.Duration
+	// ReconnectMaxAttempts bounds how many redial attempts are made before
+	// giving up; 0 means retry forever.
+	ReconnectMaxAttempts int
+
+	// SignalingFactory picks the Signaling transport a Peer uses, so
+	// callers on restrictive networks or serverless platforms can choose
+	// e.g. NewHTTPLongPollSignaling instead of the default
+	// NewWebSocketSignaling.
+	SignalingFactory func(Options) Signaling
+
+	// Configuration holds the ICE/TURN configuration. Peer does not create
+	// PeerConnections from it; a REFRESH_ICE signaling message replaces
+	// ICEServers in the Peer's own copy and pushes the new servers into the
+	// DataConnections tracked via AddDataConnection.
+	Configuration webrtc.Configuration
+
+	// SignalingCodec selects the wire encoding Socket.SendMessage and
+	// Socket's read loop use: SignalingCodecJSON (default, wire-compatible
+	// with the JS peerjs-server) or SignalingCodecMsgpack. Peer.SendMessage
+	// and HTTPLongPollSignaling always use JSON.
+	SignalingCodec string
+}
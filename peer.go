@@ -0,0 +1,203 @@
+package peer
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// signalEventPrefix namespaces Peer events raised for inbound signaling
+// message types (every type except REFRESH_ICE, which Peer handles itself,
+// standard ones included), so application handlers register via
+// peer.On("signal:SOME_TYPE", handler) without colliding with
+// PeerEventType* lifecycle events.
+const signalEventPrefix = "signal:"
+
+// PeerEventTypeOpen/Disconnected/Reconnected mirror the underlying socket's
+// connection lifecycle at the Peer level.
+const (
+	PeerEventTypeOpen         = "open"
+	PeerEventTypeDisconnected = "disconnected"
+	PeerEventTypeReconnected  = "reconnected"
+)
+
+// DataConnection is a negotiated data channel to a remote peer. Peer keeps
+// these around across a signaling reconnect instead of tearing them down,
+// since the underlying WebRTC PeerConnection survives a signaling blip.
+type DataConnection struct {
+	ConnectionID string
+	PeerID       string
+	pc           *webrtc.PeerConnection
+}
+
+// NewDataConnection wraps an already-negotiated WebRTC PeerConnection so it
+// can be tracked by a Peer via AddDataConnection. pc may be nil for callers
+// that only want REFRESH_ICE to update opts.Configuration.
+func NewDataConnection(connectionID, peerID string, pc *webrtc.PeerConnection) *DataConnection {
+	return &DataConnection{
+		ConnectionID: connectionID,
+		PeerID:       peerID,
+		pc:           pc,
+	}
+}
+
+// Peer is the local endpoint in the PeerJS signaling protocol: it owns the
+// Socket used to reach a PeerServer and the DataConnections negotiated
+// through it.
+type Peer struct {
+	Emitter
+	id     string
+	token  string
+	opts   Options
+	socket Signaling
+	log    *logrus.Entry
+
+	dcMutex         sync.Mutex
+	dataConnections map[string]*DataConnection
+}
+
+// NewPeer creates a Peer that will identify itself to the PeerServer as id.
+// The Signaling transport is chosen by opts.SignalingFactory, defaulting to
+// NewWebSocketSignaling when unset.
+func NewPeer(id string, opts Options) *Peer {
+	factory := opts.SignalingFactory
+	if factory == nil {
+		factory = NewWebSocketSignaling
+	}
+
+	p := &Peer{
+		Emitter:         NewEmitter(),
+		id:              id,
+		opts:            opts,
+		socket:          factory(opts),
+		log:             createLogger("peer", opts.Debug),
+		dataConnections: map[string]*DataConnection{},
+	}
+	p.socket.On(SocketEventTypeDisconnected, func(interface{}) { p.handleSocketDisconnected() })
+	p.socket.On(SocketEventTypeReconnected, func(interface{}) { p.handleSocketReconnected() })
+	p.socket.On(SocketEventTypeMessage, func(data interface{}) {
+		if event, ok := data.(SocketEvent); ok && event.Message != nil {
+			p.handleMessage(event.Message)
+		}
+	})
+	return p
+}
+
+// AddDataConnection tracks dc so a later REFRESH_ICE message pushes rotated
+// ICE servers into its PeerConnection via handleRefreshICE. Callers add a
+// DataConnection once it has finished negotiating.
+func (p *Peer) AddDataConnection(dc *DataConnection) {
+	p.dcMutex.Lock()
+	p.dataConnections[dc.ConnectionID] = dc
+	p.dcMutex.Unlock()
+}
+
+// RemoveDataConnection stops tracking the DataConnection with the given id,
+// e.g. once it has closed.
+func (p *Peer) RemoveDataConnection(connectionID string) {
+	p.dcMutex.Lock()
+	delete(p.dataConnections, connectionID)
+	p.dcMutex.Unlock()
+}
+
+// Send delivers a pre-encoded signaling payload through the underlying
+// Signaling transport, so "signal:<type>" handlers registered via On can
+// reply to the server.
+func (p *Peer) Send(msg []byte) error {
+	return p.socket.Send(msg)
+}
+
+// SendMessage JSON-encodes msg and sends it through the underlying
+// Signaling transport.
+func (p *Peer) SendMessage(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return p.socket.Send(data)
+}
+
+// On registers handler for a Peer lifecycle event (PeerEventType*) or, via
+// the "signal:<type>" prefix, for an inbound signaling message type, such as
+// a non-standard one a custom PeerServer plugin sends (e.g. "signal:PRESENCE").
+func (p *Peer) On(event string, handler EventHandler) {
+	p.Emitter.On(event, handler)
+}
+
+// handleMessage dispatches an inbound signaling Message. REFRESH_ICE is
+// handled here directly so long-lived peers rotate TURN credentials without
+// reconnecting; anything else is republished as "signal:<type>" for
+// application handlers registered via On.
+func (p *Peer) handleMessage(msg *Message) {
+	if msg.Type == RefreshICEMessageType {
+		p.handleRefreshICE(msg.Payload)
+		return
+	}
+
+	if msg.Type == "OPEN" {
+		p.Emit(PeerEventTypeOpen, nil)
+	}
+
+	p.Emit(signalEventPrefix+msg.Type, msg)
+}
+
+// handleRefreshICE applies a REFRESH_ICE message's ICE server list to
+// opts.Configuration and to every tracked DataConnection's PeerConnection,
+// so rotated TURN credentials reach in-flight connections immediately.
+func (p *Peer) handleRefreshICE(payload Payload) {
+	servers, err := ParseRefreshICE(payload)
+	if err != nil {
+		p.log.Warnf("Failed to parse REFRESH_ICE message: %s", err)
+		return
+	}
+
+	p.opts.Configuration.ICEServers = servers
+
+	p.dcMutex.Lock()
+	defer p.dcMutex.Unlock()
+	for _, dc := range p.dataConnections {
+		if dc.pc == nil {
+			continue
+		}
+		if err := ApplyRefreshICE(dc.pc, servers); err != nil {
+			p.log.Warnf("Failed to apply refreshed ICE servers to %s: %s", dc.ConnectionID, err)
+		}
+	}
+}
+
+// Start connects to the PeerServer with the given token, as issued by the
+// server for this Peer's id.
+func (p *Peer) Start(token string) error {
+	p.token = token
+	return p.socket.Start(p.id, token)
+}
+
+// Close tears down the signaling connection and stops tracking all
+// DataConnections; their PeerConnections are not closed.
+func (p *Peer) Close() error {
+	p.dcMutex.Lock()
+	p.dataConnections = map[string]*DataConnection{}
+	p.dcMutex.Unlock()
+	return p.socket.Close()
+}
+
+// handleSocketDisconnected reacts to a transport-level drop. It deliberately
+// does NOT tear down dataConnections: a signaling hiccup doesn't mean the
+// already-negotiated WebRTC PeerConnections died, and Socket itself will
+// keep retrying when ReconnectEnabled is set.
+func (p *Peer) handleSocketDisconnected() {
+	p.log.Warn("Signaling disconnected, DataConnections are left intact")
+	p.Emit(PeerEventTypeDisconnected, nil)
+}
+
+// handleSocketReconnected resumes normal operation once the socket has
+// redialed with the same id/token. The PeerServer re-confirms registration
+// by sending an OPEN message on the new connection, so there's no separate
+// client-driven handshake step here beyond letting the existing
+// DataConnections keep running undisturbed.
+func (p *Peer) handleSocketReconnected() {
+	p.log.Info("Signaling reconnected, resuming with existing DataConnections")
+	p.Emit(PeerEventTypeReconnected, nil)
+}
@@ -0,0 +1,43 @@
+package peer
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Server is a minimal PeerServer-side message router, used when this
+// package is embedded as (part of) a peerjs-go server process. It answers
+// the application-level heartbeat Socket sends on the client side, so two
+// peerjs-go instances can talk to each other symmetrically without one end
+// needing the reference JS PeerServer in the middle.
+type Server struct {
+	opts Options
+	log  *logrus.Entry
+}
+
+// NewServer creates a Server message router.
+func NewServer(opts Options) *Server {
+	return &Server{
+		opts: opts,
+		log:  createLogger("server", opts.Debug),
+	}
+}
+
+// HandleMessage routes an inbound Message from a connected client, replying
+// through send for message types the router answers itself. It returns nil
+// (without calling send) for any message type it doesn't own, so callers
+// can fall through to their own routing.
+func (srv *Server) HandleMessage(send func([]byte) error, msg *Message) error {
+	if msg.Type != heartbeatMessageType {
+		return nil
+	}
+
+	ack, err := json.Marshal(Message{Type: heartbeatMessageType, Dst: msg.Src})
+	if err != nil {
+		return err
+	}
+
+	srv.log.Debugf("Replying to heartbeat from %s", msg.Src)
+	return send(ack)
+}
@@ -0,0 +1,36 @@
+package peer
+
+// Signaling abstracts the transport carrying signaling Messages between a
+// Peer and a PeerServer, so the websocket wire format used by the reference
+// server is one option among several rather than a hardcoded dependency.
+// Implementations embed Emitter and emit SocketEventType* events, which is
+// why On/Emit are part of the interface rather than left as an
+// implementation detail. Socket emits SocketEventTypeMessage,
+// SocketEventTypeDisconnected and, when it redials, SocketEventTypeReconnected;
+// HTTPLongPollSignaling emits only SocketEventTypeMessage and
+// SocketEventTypeDisconnected, since it does not reconnect after a failed poll.
+type Signaling interface {
+	// Start opens the transport and authenticates with the given id/token.
+	Start(id string, token string) error
+
+	// Send delivers an outgoing Message to the server.
+	Send(msg []byte) error
+
+	// Close tears down the transport.
+	Close() error
+
+	// On subscribes handler to a SocketEventType* event.
+	On(event string, handler EventHandler)
+
+	// Emit notifies subscribers of a SocketEventType* event.
+	Emit(event string, data interface{})
+}
+
+// WebSocketSignaling is the default Signaling backed by a persistent
+// gorilla/websocket connection, as used by the reference peerjs-server.
+type WebSocketSignaling = Socket
+
+// NewWebSocketSignaling creates a Signaling instance backed by a websocket.
+func NewWebSocketSignaling(opts Options) Signaling {
+	return NewSocket(opts)
+}
@@ -1,8 +1,10 @@
 package peer
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
 	"strconv"
 	"sync"
 	"time"
@@ -11,6 +13,46 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// heartbeatMessageType marks the application-level keepalive exchanged on
+// top of the protocol-level websocket ping/pong.
+const heartbeatMessageType = "HEARTBEAT"
+
+// ErrHeartbeatTimeout is emitted via SocketEventTypeDisconnected when nothing
+// (pong, heartbeat ack or any other message) is received from the server
+// within PingInterval * PingTimeoutFactor.
+var ErrHeartbeatTimeout = errors.New("peer: heartbeat timeout, connection considered dead")
+
+// maxQueuedMessages bounds the outgoing queue buffered while the socket is
+// disconnected, so a peer stuck offline doesn't grow memory unbounded.
+const maxQueuedMessages = 256
+
+// queuedFrame is an outgoing message buffered while disconnected, along
+// with the websocket frame type it must be replayed as so a binary
+// (msgpack) payload doesn't get replayed as a text frame or vice versa.
+type queuedFrame struct {
+	frameType int
+	data      []byte
+}
+
+// Default backoff bounds used when Options doesn't set its own.
+const (
+	DefaultReconnectInitialDelay = 1 * time.Second
+	DefaultReconnectMaxDelay     = 30 * time.Second
+)
+
+// DefaultPingTimeoutFactor is used when Options.PingTimeoutFactor is unset:
+// a missed pong/heartbeat for PingInterval * this factor is a dead link.
+const DefaultPingTimeoutFactor = 2.0
+
+// SocketEvent types emitted by Socket (and other Signaling implementations)
+// for subscribers registered via Emitter.On.
+const (
+	SocketEventTypeMessage      = "message"
+	SocketEventTypeDisconnected = "disconnected"
+	SocketEventTypeReconnecting = "reconnecting"
+	SocketEventTypeReconnected  = "reconnected"
+)
+
 // SocketEvent carries an event from the socket
 type SocketEvent struct {
 	Type    string
@@ -18,9 +60,11 @@ type SocketEvent struct {
 	Error   error
 }
 
-//NewSocket create a socket instance
-func NewSocket(opts Options) Socket {
-	s := Socket{
+//NewSocket create a socket instance. It returns a pointer rather than a
+//value because Socket embeds mutexes (its own, and Emitter's) that must
+//never be copied once constructed.
+func NewSocket(opts Options) *Socket {
+	s := &Socket{
 		Emitter: NewEmitter(),
 		log:     createLogger("socket", opts.Debug),
 	}
@@ -29,16 +73,68 @@ func NewSocket(opts Options) Socket {
 	return s
 }
 
-//Socket abstract websocket exposing an event emitter like interface
+//Socket abstract websocket exposing an event emitter like interface.
+//It is the default Signaling implementation; see WebSocketSignaling.
 type Socket struct {
 	Emitter
-	id           string
-	opts         Options
-	baseURL      string
-	disconnected bool
-	conn         *websocket.Conn
-	log          *logrus.Entry
-	mutex        sync.Mutex
+	id             string
+	token          string
+	opts           Options
+	baseURL        string
+	disconnected   bool
+	reconnecting   bool
+	closedByUser   bool
+	reconnectTries int
+	conn           *websocket.Conn
+	log            *logrus.Entry
+	mutex          sync.Mutex
+	queue          []queuedFrame
+	lastPong       time.Time
+	// generation is bumped on every dial and closed over by that dial's
+	// ping/read goroutines, so a goroutine belonging to a since-replaced
+	// connection can tell it's stale and must not touch the current one.
+	generation uint64
+}
+
+// RTT returns how long ago anything (pong, heartbeat ack or any other
+// message) was last received from the server, or since the connection was
+// dialed if nothing has arrived yet, which callers can poll to monitor link
+// health.
+func (s *Socket) RTT() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.lastPong.IsZero() {
+		return 0
+	}
+	return time.Since(s.lastPong)
+}
+
+func (s *Socket) heartbeatTimeout() time.Duration {
+	factor := s.opts.PingTimeoutFactor
+	if factor <= 0 {
+		factor = DefaultPingTimeoutFactor
+	}
+	return time.Duration(float64(s.opts.PingInterval) * factor) * time.Millisecond
+}
+
+func (s *Socket) refreshDeadline() {
+	s.mutex.Lock()
+	s.lastPong = time.Now()
+	conn := s.conn
+	s.mutex.Unlock()
+	if conn != nil {
+		conn.SetReadDeadline(time.Now().Add(s.heartbeatTimeout()))
+	}
+}
+
+func (s *Socket) lastPongExpired() bool {
+	s.mutex.Lock()
+	last := s.lastPong
+	s.mutex.Unlock()
+	if last.IsZero() {
+		return false
+	}
+	return time.Since(last) > s.heartbeatTimeout()
 }
 
 func (s *Socket) buildBaseURL() string {
@@ -47,7 +143,7 @@ func (s *Socket) buildBaseURL() string {
 		proto = "wss"
 	}
 	port := strconv.Itoa(s.opts.Port)
-	return fmt.Sprintf(
+	url := fmt.Sprintf(
 		"%s://%s:%s%s/peerjs?key=%s",
 		proto,
 		s.opts.Host,
@@ -55,6 +151,14 @@ func (s *Socket) buildBaseURL() string {
 		s.opts.Path,
 		s.opts.Key,
 	)
+	if s.opts.SignalingCodec == SignalingCodecMsgpack {
+		url += "&format=msgpack"
+	}
+	return url
+}
+
+func (s *Socket) codec() codec {
+	return codecFor(s.opts.SignalingCodec)
 }
 
 //Start initiate the connection
@@ -64,17 +168,39 @@ func (s *Socket) Start(id string, token string) error {
 		return nil
 	}
 
+	s.id = id
+	s.token = token
+	s.closedByUser = false
+
 	if s.baseURL == "" {
 		s.baseURL = s.buildBaseURL()
 	}
 
-	url := s.baseURL + fmt.Sprintf("&id=%s&token=%s", id, token)
+	if err := s.dial(); err != nil {
+		return err
+	}
+
+	s.disconnected = false
+	s.reconnecting = false
+	s.reconnectTries = 0
+	s.flushQueue()
+
+	return nil
+}
+
+func (s *Socket) dial() error {
+	url := s.baseURL + fmt.Sprintf("&id=%s&token=%s", s.id, s.token)
 	s.log.Debugf("Connecting to %s", url)
 	c, _, err := websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		return err
 	}
+
+	s.mutex.Lock()
 	s.conn = c
+	s.generation++
+	gen := s.generation
+	s.mutex.Unlock()
 
 	// s.conn.SetCloseHandler(func(code int, text string) error {
 	// 	s.log.Debug("Called close handler")
@@ -83,25 +209,45 @@ func (s *Socket) Start(id string, token string) error {
 	// 	return nil
 	// })
 
+	c.SetPongHandler(func(string) error {
+		s.refreshDeadline()
+		return nil
+	})
+	s.refreshDeadline()
+
 	//  ws ping
 	go func() {
 		ticker := time.NewTicker(time.Millisecond * time.Duration(s.opts.PingInterval))
-		defer func() {
-			ticker.Stop()
-			s.Close()
-		}()
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				if s.conn == nil {
+				if s.staleGeneration(gen) {
 					return
 				}
+
+				if s.lastPongExpired() {
+					s.handleDisconnect(gen, ErrHeartbeatTimeout)
+					return
+				}
+
 				s.mutex.Lock()
-				if err := s.conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+				if s.generation != gen || s.conn == nil {
 					s.mutex.Unlock()
 					return
 				}
+				conn := s.conn
+				err := conn.WriteMessage(websocket.PingMessage, []byte{})
 				s.mutex.Unlock()
+				if err != nil {
+					s.handleDisconnect(gen, err)
+					return
+				}
+
+				if err := s.SendMessage(&Message{Type: heartbeatMessageType}); err != nil {
+					s.handleDisconnect(gen, err)
+					return
+				}
 				break
 			}
 		}
@@ -110,34 +256,50 @@ func (s *Socket) Start(id string, token string) error {
 	// collect messages
 	go func() {
 		for {
-			if s.conn == nil {
+			if s.staleGeneration(gen) {
 				return
 			}
 
-			msgType, raw, err := s.conn.ReadMessage()
+			msgType, raw, err := c.ReadMessage()
 			if err != nil {
 				if ce, ok := err.(*websocket.CloseError); ok {
 					switch ce.Code {
 					case websocket.CloseNormalClosure,
 						websocket.CloseGoingAway,
 						websocket.CloseNoStatusReceived:
+						s.handleDisconnect(gen, nil)
 						return
 					}
 				}
 				s.log.Warnf("WS read error: %s", err)
-				continue
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					err = ErrHeartbeatTimeout
+				}
+				s.handleDisconnect(gen, err)
+				return
 			}
 
 			s.log.Infof("WS recv: %d %s", msgType, raw)
 
-			if msgType == websocket.TextMessage {
+			if msgType == websocket.TextMessage || msgType == websocket.BinaryMessage {
+
+				frameType := textFrame
+				if msgType == websocket.BinaryMessage {
+					frameType = binaryFrame
+				}
 
 				msg := Message{}
-				err = json.Unmarshal(raw, &msg)
+				err = s.codec().Decode(frameType, raw, &msg)
 				if err != nil {
 					s.log.Errorf("Failed to decode message=%s %s", string(raw), err)
 				}
 
+				if msg.Type == heartbeatMessageType {
+					s.refreshDeadline()
+					continue
+				}
+
+				s.refreshDeadline()
 				s.Emit(SocketEventTypeMessage, SocketEvent{SocketEventTypeMessage, &msg, err})
 			}
 
@@ -147,8 +309,153 @@ func (s *Socket) Start(id string, token string) error {
 	return nil
 }
 
+// staleGeneration reports whether gen no longer identifies the socket's
+// current connection, meaning the calling goroutine belongs to a
+// connection that has already been superseded or closed.
+func (s *Socket) staleGeneration(gen uint64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.conn == nil || s.generation != gen
+}
+
+// handleDisconnect closes the connection identified by gen (ignoring the
+// call if it no longer matches the socket's current connection, e.g. a
+// stale read/ping goroutine unblocking after a newer dial already
+// succeeded), marks the socket disconnected and, unless the user closed it
+// on purpose, kicks off the supervised reconnect loop.
+func (s *Socket) handleDisconnect(gen uint64, err error) {
+	s.mutex.Lock()
+	if s.generation != gen || s.disconnected {
+		s.mutex.Unlock()
+		return
+	}
+	s.disconnected = true
+	conn := s.conn
+	s.conn = nil
+	s.mutex.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+
+	s.Emit(SocketEventTypeDisconnected, SocketEvent{SocketEventTypeDisconnected, nil, err})
+
+	if s.closedByUser || !s.opts.ReconnectEnabled {
+		return
+	}
+
+	go s.reconnectLoop()
+}
+
+// reconnectLoop redials the base URL with the same id/token, backing off
+// exponentially with jitter between attempts, up to ReconnectMaxAttempts
+// (0 means retry forever).
+func (s *Socket) reconnectLoop() {
+	s.mutex.Lock()
+	if s.reconnecting || s.closedByUser {
+		s.mutex.Unlock()
+		return
+	}
+	s.reconnecting = true
+	s.mutex.Unlock()
+
+	// Every exit path below — success, exhausting ReconnectMaxAttempts, or
+	// the user calling Close() mid-backoff — must clear s.reconnecting, or
+	// the next handleDisconnect's reconnectLoop call no-ops at the guard
+	// above forever, even though ReconnectEnabled is still true.
+	defer func() {
+		s.mutex.Lock()
+		s.reconnecting = false
+		s.mutex.Unlock()
+	}()
+
+	s.Emit(SocketEventTypeReconnecting, SocketEvent{SocketEventTypeReconnecting, nil, nil})
+
+	delay := s.opts.ReconnectInitialDelay
+	if delay <= 0 {
+		delay = DefaultReconnectInitialDelay
+	}
+	maxDelay := s.opts.ReconnectMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultReconnectMaxDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		if s.opts.ReconnectMaxAttempts > 0 && attempt > s.opts.ReconnectMaxAttempts {
+			s.log.Errorf("Giving up reconnecting after %d attempts", s.opts.ReconnectMaxAttempts)
+			return
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(delay) / 2))
+		sleep := delay + jitter
+		if sleep > maxDelay {
+			sleep = maxDelay
+		}
+		s.log.Debugf("Reconnecting in %s (attempt %d)", sleep, attempt)
+		time.Sleep(sleep)
+
+		if s.closedByUser {
+			return
+		}
+
+		if err := s.dial(); err != nil {
+			s.log.Warnf("Reconnect attempt %d failed: %s", attempt, err)
+			delay *= 2
+			if delay > maxDelay {
+				delay = maxDelay
+			}
+			continue
+		}
+
+		s.mutex.Lock()
+		s.disconnected = false
+		s.reconnectTries = attempt
+		s.mutex.Unlock()
+
+		s.flushQueue()
+		s.Emit(SocketEventTypeReconnected, SocketEvent{SocketEventTypeReconnected, nil, nil})
+		return
+	}
+}
+
+// flushQueue replays any frames buffered while the socket was disconnected,
+// each using the frame type it was originally queued with.
+func (s *Socket) flushQueue() {
+	s.mutex.Lock()
+	pending := s.queue
+	s.queue = nil
+	s.mutex.Unlock()
+
+	for _, f := range pending {
+		if err := s.writeOrQueue(f.frameType, f.data); err != nil {
+			s.log.Warnf("Failed to replay buffered message: %s", err)
+		}
+	}
+}
+
+// writeOrQueue writes data as the given frame type if connected, or buffers
+// it (when reconnection is enabled and the socket wasn't closed by the
+// user) to be replayed by flushQueue once the link is back.
+func (s *Socket) writeOrQueue(frameType int, data []byte) error {
+	s.mutex.Lock()
+	if s.conn == nil {
+		defer s.mutex.Unlock()
+		if s.opts.ReconnectEnabled && !s.closedByUser {
+			if len(s.queue) < maxQueuedMessages {
+				s.queue = append(s.queue, queuedFrame{frameType: frameType, data: data})
+			} else {
+				s.log.Warnf("Outgoing queue full, dropping message")
+			}
+		}
+		return nil
+	}
+	defer s.mutex.Unlock()
+	return s.conn.WriteMessage(frameType, data)
+}
+
 //Close close the websocket connection
 func (s *Socket) Close() error {
+	s.closedByUser = true
 	if s.disconnected {
 		return nil
 	}
@@ -163,17 +470,38 @@ func (s *Socket) Close() error {
 	if err != nil {
 		s.log.Warnf("WS close error: %s", err)
 	}
+	s.mutex.Lock()
 	s.disconnected = true
 	s.conn = nil
+	s.generation++
+	s.mutex.Unlock()
 	return err
 }
 
-//Send send a message
+//Send send an already JSON-encoded message as a text frame, buffering it
+//when the socket is disconnected and reconnection is enabled so it can be
+//replayed once the link is back. This is the legacy, codec-agnostic path:
+//it always writes a TextMessage frame, matching what pre-existing callers
+//that json.Marshal a Message themselves expect. Callers that want the
+//negotiated SignalingCodec (e.g. msgpack) applied should use SendMessage
+//instead.
 func (s *Socket) Send(msg []byte) error {
-	if s.conn == nil {
-		return nil
+	return s.writeOrQueue(websocket.TextMessage, msg)
+}
+
+//SendMessage is the codec-aware send path: it encodes msg with the
+//negotiated SignalingCodec and writes it using the matching frame type
+//(text for JSON, binary for msgpack), buffering/replaying the same way
+//Send does when the socket is disconnected.
+func (s *Socket) SendMessage(msg *Message) error {
+	frameType, data, err := s.codec().Encode(msg)
+	if err != nil {
+		return err
 	}
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.conn.WriteMessage(websocket.TextMessage, msg)
+
+	wsFrameType := websocket.TextMessage
+	if frameType == binaryFrame {
+		wsFrameType = websocket.BinaryMessage
+	}
+	return s.writeOrQueue(wsFrameType, data)
 }
\ No newline at end of file
@@ -0,0 +1,130 @@
+package peer
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testWSServer starts an httptest.Server serving handler (which does its own
+// websocket upgrade), returning the host/port a Socket's Options should dial
+// and a func that shuts the server down.
+func testWSServer(t *testing.T, handler http.HandlerFunc) (host string, port int, close func()) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, server.Close
+}
+
+func TestSocket_StartSendAndReceive(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	host, port, closeServer := testWSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msgType, raw, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(msgType, raw); err != nil {
+				return
+			}
+		}
+	})
+	defer closeServer()
+
+	s := NewSocket(Options{Host: host, Port: port, PingInterval: 60000})
+	messages := make(chan *Message, 1)
+	s.On(SocketEventTypeMessage, func(data interface{}) {
+		if event, ok := data.(SocketEvent); ok {
+			messages <- event.Message
+		}
+	})
+
+	if err := s.Start("peer1", "token1"); err != nil {
+		t.Fatalf("Start failed: %s", err)
+	}
+	defer s.Close()
+
+	if err := s.SendMessage(&Message{Type: "OFFER", Src: "peer1"}); err != nil {
+		t.Fatalf("SendMessage failed: %s", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.Type != "OFFER" {
+			t.Fatalf("expected echoed OFFER message, got %q", msg.Type)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for echoed message")
+	}
+}
+
+// TestSocket_ReconnectLoopResetsAfterGivingUp is a regression test for a bug
+// where reconnectLoop only cleared s.reconnecting on the success path:
+// exhausting ReconnectMaxAttempts (or the user calling Close mid-backoff)
+// left it stuck true forever, silently disabling all future reconnects.
+func TestSocket_ReconnectLoopResetsAfterGivingUp(t *testing.T) {
+	var upgraded int32
+	upgrader := websocket.Upgrader{}
+	host, port, closeServer := testWSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Allow exactly one successful upgrade, then drop it immediately so
+		// the client disconnects and starts reconnecting. Every redial
+		// after that is refused at the HTTP layer, so dial() keeps failing
+		// until ReconnectMaxAttempts is exhausted.
+		if atomic.CompareAndSwapInt32(&upgraded, 0, 1) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			conn.Close()
+			return
+		}
+		http.Error(w, "refusing further connections", http.StatusServiceUnavailable)
+	})
+	defer closeServer()
+
+	s := NewSocket(Options{
+		Host:                  host,
+		Port:                  port,
+		PingInterval:          60000,
+		ReconnectEnabled:      true,
+		ReconnectInitialDelay: 5 * time.Millisecond,
+		ReconnectMaxDelay:     10 * time.Millisecond,
+		ReconnectMaxAttempts:  2,
+	})
+
+	if err := s.Start("peer1", "token1"); err != nil {
+		t.Fatalf("Start failed: %s", err)
+	}
+	defer s.Close()
+
+	waitForReconnecting := func(want bool) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			s.mutex.Lock()
+			reconnecting := s.reconnecting
+			s.mutex.Unlock()
+			if reconnecting == want {
+				return true
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	if !waitForReconnecting(true) {
+		t.Fatal("reconnectLoop never started after the connection was dropped")
+	}
+	if !waitForReconnecting(false) {
+		t.Fatal("reconnecting flag stayed true after ReconnectMaxAttempts was exhausted")
+	}
+}